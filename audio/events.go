@@ -0,0 +1,196 @@
+package audio
+
+import (
+	"sort"
+	"time"
+
+	"github.com/CyCoreSystems/ari"
+	v2 "github.com/CyCoreSystems/ari/v2"
+
+	"golang.org/x/net/context"
+)
+
+// ProgressTick is the interval at which PlayWithEvents emits Progress events
+// while a playback is actively playing.
+var ProgressTick = 250 * time.Millisecond
+
+// PlaybackEventKind identifies the kind of event delivered on a
+// PlaybackEvent channel.
+type PlaybackEventKind int
+
+// Kinds of PlaybackEvent.
+const (
+	EventStarted PlaybackEventKind = iota
+	EventProgress
+	EventPaused
+	EventResumed
+	EventFinished
+	EventDTMF
+)
+
+func (k PlaybackEventKind) String() string {
+	switch k {
+	case EventStarted:
+		return "Started"
+	case EventProgress:
+		return "Progress"
+	case EventPaused:
+		return "Paused"
+	case EventResumed:
+		return "Resumed"
+	case EventFinished:
+		return "Finished"
+	case EventDTMF:
+		return "DTMF"
+	default:
+		return "Unknown"
+	}
+}
+
+// PlaybackEvent is a single, time-ordered event describing the progress of
+// a playback started by PlayWithEvents.
+type PlaybackEvent struct {
+	Offset time.Duration
+	Kind   PlaybackEventKind
+	Digit  rune
+}
+
+// PlayWithEvents plays mediaURI and returns a channel of time-ordered
+// PlaybackEvents describing its progress, including inline DTMF digits
+// received while it plays. The channel is closed once the playback has
+// finished, failed, or ctx is done.
+//
+// Since ARI does not deliver true playback position events, Offset and the
+// Progress ticks are reconstructed from wall-clock time since PlaybackStarted,
+// adjusted for any time spent in the Paused state.
+func PlayWithEvents(ctx context.Context, bus ari.Subscriber, p Player, mediaURI string) (<-chan PlaybackEvent, error) {
+	pb, err := PlayAsync(bus, p, mediaURI)
+	if err != nil {
+		return nil, err
+	}
+
+	dtmf := bus.Subscribe("ChannelDtmfReceived")
+
+	events := make(chan PlaybackEvent, 16)
+
+	go func() {
+		defer close(events)
+		defer dtmf.Cancel()
+		defer pb.Cancel()
+
+		select {
+		case <-pb.StartCh():
+		case <-ctx.Done():
+			return
+		}
+		if pb.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		var pausedAt time.Time
+		var pausedTotal time.Duration
+
+		sendEvent(events, PlaybackEvent{Kind: EventStarted})
+
+		ticker := time.NewTicker(ProgressTick)
+		defer ticker.Stop()
+
+		lastState := pb.State()
+		for {
+			select {
+			case <-pb.StopCh():
+				sendEvent(events, PlaybackEvent{Offset: time.Since(start) - pausedTotal, Kind: EventFinished})
+				return
+			case <-ctx.Done():
+				return
+			case s := <-pb.StateCh():
+				switch {
+				case s == Paused && lastState != Paused:
+					pausedAt = time.Now()
+					sendEvent(events, PlaybackEvent{Offset: time.Since(start) - pausedTotal, Kind: EventPaused})
+				case s == Playing && lastState == Paused:
+					pausedTotal += time.Since(pausedAt)
+					sendEvent(events, PlaybackEvent{Offset: time.Since(start) - pausedTotal, Kind: EventResumed})
+				}
+				lastState = s
+			case v := <-dtmf.C:
+				e, ok := v.(*v2.ChannelDtmfReceived)
+				if !ok || e.Digit == "" {
+					continue
+				}
+				sendEvent(events, PlaybackEvent{
+					Offset: time.Since(start) - pausedTotal,
+					Kind:   EventDTMF,
+					Digit:  []rune(e.Digit)[0],
+				})
+			case <-ticker.C:
+				if lastState != Playing {
+					continue
+				}
+				sendEvent(events, PlaybackEvent{Offset: time.Since(start) - pausedTotal, Kind: EventProgress})
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// sendEvent delivers ev to events without blocking, dropping it (and
+// logging) if the caller isn't keeping up, so a stalled subscriber can't
+// wedge PlayWithEvents' goroutine open forever instead of reacting to
+// ctx.Done()/pb.StopCh().
+func sendEvent(events chan PlaybackEvent, ev PlaybackEvent) {
+	select {
+	case events <- ev:
+	default:
+		Logger.Debug("Dropping playback event for slow PlayWithEvents subscriber", "kind", ev.Kind)
+	}
+}
+
+// ReportProgress watches pb and invokes cb once for each threshold fraction
+// of duration it passes, in ascending order, then stops watching. If no
+// thresholds are given, it defaults to 0.25, 0.5 and 0.9.
+//
+// Since ARI does not report a playback's true position, fractions are
+// computed from wall-clock time elapsed since the playback started against
+// the caller-supplied duration, which must be known out of band (e.g. from
+// the media file's metadata).
+func ReportProgress(pb *Playback, duration time.Duration, cb func(fraction float64), thresholds ...float64) {
+	if duration <= 0 || cb == nil {
+		return
+	}
+
+	if len(thresholds) == 0 {
+		thresholds = []float64{0.25, 0.5, 0.9}
+	} else {
+		thresholds = append([]float64(nil), thresholds...)
+		sort.Float64s(thresholds)
+	}
+
+	go func() {
+		<-pb.StartCh()
+		if pb.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		next := 0
+
+		ticker := time.NewTicker(ProgressTick)
+		defer ticker.Stop()
+
+		for next < len(thresholds) {
+			select {
+			case <-pb.StopCh():
+				return
+			case <-ticker.C:
+				fraction := float64(time.Since(start)) / float64(duration)
+				for next < len(thresholds) && fraction >= thresholds[next] {
+					cb(thresholds[next])
+					next++
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+package audio
+
+import "log/slog"
+
+// Logger is the package-level logger used by the audio package. It defaults
+// to the standard library's default logger and may be overridden by the
+// caller to route playback diagnostics wherever the application logs.
+var Logger = slog.Default()
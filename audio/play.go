@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/CyCoreSystems/ari"
-	v2 "github.com/CyCoreSystems/ari/v2"
 
 	"golang.org/x/net/context"
 )
@@ -38,127 +37,74 @@ func Play(ctx context.Context, bus ari.Subscriber, p Player, mediaURI string) er
 	return pb.Err()
 }
 
+// PlayOptions overrides the package-level PlaybackStartTimeout and
+// MaxPlaybackTime for a single invocation of PlayAsyncWithOptions or
+// PlayWithRetry, since the package-level variables are not safe to tune
+// concurrently. A zero field falls back to the package-level variable.
+type PlayOptions struct {
+	PlaybackStartTimeout time.Duration
+	MaxPlaybackTime      time.Duration
+}
+
 // PlayAsync plays audio to the given Player, returning a Playback object
 func PlayAsync(bus ari.Subscriber, p Player, mediaURI string) (*Playback, error) {
+	return playAsync(p, mediaURI, PlaybackStartTimeout, MaxPlaybackTime, NewPlaybackTracker(bus))
+}
 
-	var pb Playback
+// PlayAsyncWithOptions is like PlayAsync but allows the caller to override
+// PlaybackStartTimeout and MaxPlaybackTime for this invocation only.
+func PlayAsyncWithOptions(bus ari.Subscriber, p Player, mediaURI string, opts PlayOptions) (*Playback, error) {
+	startTimeout := opts.PlaybackStartTimeout
+	if startTimeout <= 0 {
+		startTimeout = PlaybackStartTimeout
+	}
+
+	maxPlayTime := opts.MaxPlaybackTime
+	if maxPlayTime <= 0 {
+		maxPlayTime = MaxPlaybackTime
+	}
 
-	// subscribe to ARI events
-	s := bus.Subscribe("PlaybackStarted", "PlaybackFinished")
+	return playAsync(p, mediaURI, startTimeout, maxPlayTime, NewPlaybackTracker(bus))
+}
+
+// PlayAsyncWithTracker is like PlayAsync but lets the caller supply the
+// PlaybackTracker used to watch the playback, instead of PlayAsync's default
+// of opening a new subscription per call. Pass a shared *MultiplexedTracker
+// in high-concurrency dialplans to track many concurrent playbacks off of a
+// single subscription, or a recorded EventSource-backed tracker in tests.
+func PlayAsyncWithTracker(p Player, mediaURI string, tracker PlaybackTracker) (*Playback, error) {
+	return playAsync(p, mediaURI, PlaybackStartTimeout, MaxPlaybackTime, tracker)
+}
+
+func playAsync(p Player, mediaURI string, startTimeout, maxPlayTime time.Duration, tracker PlaybackTracker) (*Playback, error) {
+
+	var pb Playback
 
 	// start playback
 	h, err := p.Play(mediaURI)
 	if err != nil {
-		s.Cancel()
 		return nil, err
 	}
 
 	// build return value
 
-	quitCh := make(chan struct{})
-
 	pb.handle = h
 	pb.stopCh = make(chan struct{})
 	pb.startCh = make(chan struct{})
-	pb.quitCh = quitCh
+	pb.quitCh = make(chan struct{})
+	pb.stateCh = make(chan PlaybackState, 16)
+	pb.jumpDuration = DefaultJumpDuration
+	pb.setState(Starting)
 
 	// get playback data/identifier
 
 	// NOTE: this is where we may want to be able to access handle.ID directly?
 	data, err := h.Data()
 	if err != nil {
-		s.Cancel()
 		return nil, err
 	}
 
-	go func() {
-
-		defer s.Cancel()
-
-		id := data.ID
-
-		// Wait for the playback to start
-		startTimer := time.After(PlaybackStartTimeout)
-	PlaybackStartLoop:
-		for {
-			select {
-			case <-quitCh:
-				close(pb.startCh)
-				close(pb.stopCh)
-				return
-			case v := <-s.C:
-				if v == nil {
-					Logger.Debug("Nil event received")
-					continue PlaybackStartLoop
-				}
-				switch v.GetType() {
-				case "PlaybackStarted":
-					e := v.(*v2.PlaybackStarted)
-					if e.Playback.ID != id {
-						Logger.Debug("Ignoring unrelated playback", "expected", id, "got", e.Playback.ID)
-						continue PlaybackStartLoop
-					}
-					Logger.Debug("Playback started", "h", h)
-					break PlaybackStartLoop
-				case "PlaybackFinished":
-					e := v.(*v2.PlaybackFinished)
-					if e.Playback.ID != id {
-						Logger.Debug("Ignoring unrelated playback")
-						continue PlaybackStartLoop
-					}
-					Logger.Debug("Playback stopped (before PlaybackStated received)", "h", h)
-					close(pb.startCh)
-					close(pb.stopCh)
-					return
-				default:
-					Logger.Debug("Unhandled e.Type", v.GetType())
-					continue PlaybackStartLoop
-				}
-			case <-startTimer:
-				Logger.Error("Playback timed out", "h", h)
-				pb.err = timeoutErr{"Timeout waiting for start of playback"}
-				close(pb.startCh)
-				close(pb.stopCh)
-				return
-			}
-		}
-
-		// trigger playback start signal and defer playback stop signal
-		close(pb.startCh)
-		defer close(pb.stopCh)
-
-		// Playback has started.  Wait for it to finish
-		stopTimer := time.After(MaxPlaybackTime)
-	PlaybackStopLoop:
-		for {
-			select {
-			case <-quitCh:
-				return
-			case v := <-s.C:
-				if v == nil {
-					Logger.Debug("Nil event received")
-					continue PlaybackStopLoop
-				}
-				switch v.GetType() {
-				case "PlaybackFinished":
-					e := v.(*v2.PlaybackFinished)
-					if e.Playback.ID != id {
-						Logger.Debug("Ignoring unrelated playback")
-						continue PlaybackStopLoop
-					}
-					Logger.Debug("Playback stopped", "h", h)
-					return
-				default:
-					Logger.Debug("Unhandled e.Type", v.GetType())
-					continue PlaybackStopLoop
-				}
-			case <-stopTimer:
-				Logger.Error("Playback timed out", "h", h)
-				pb.err = timeoutErr{"Timeout waiting for stop of playback"}
-				return
-			}
-		}
-	}()
+	go pb.watch(tracker, data.ID, startTimeout, maxPlayTime)
 
 	return &pb, err
 }
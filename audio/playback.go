@@ -0,0 +1,369 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/CyCoreSystems/ari"
+
+	"golang.org/x/net/context"
+)
+
+// DefaultJumpDuration is the jump duration reported by a Playback's
+// JumpDuration accessor when none has been explicitly set via
+// SetJumpDuration. Asterisk's actual skip amount is fixed per-channel at
+// dial time, so this only affects how callers interpret Forward/Reverse.
+var DefaultJumpDuration = 3 * time.Second
+
+// PlaybackState describes where a Playback is in its lifecycle.
+type PlaybackState int
+
+// Playback states. Transitions are monotonic (Idle -> Starting -> Playing
+// -> Stopped, or -> Failed at any point) with the single exception of
+// Paused, which may transition back and forth with Playing.
+const (
+	Idle PlaybackState = iota
+	Starting
+	Playing
+	Paused
+	Stopped
+	Failed
+)
+
+func (s PlaybackState) String() string {
+	switch s {
+	case Idle:
+		return "Idle"
+	case Starting:
+		return "Starting"
+	case Playing:
+		return "Playing"
+	case Paused:
+		return "Paused"
+	case Stopped:
+		return "Stopped"
+	case Failed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// pendingOp records a control operation requested before the playback has
+// actually started, to be applied once it does.
+type pendingOp int
+
+const (
+	opNone pendingOp = iota
+	opPause
+)
+
+// Playback represents an asynchronous audio playback operation started by
+// PlayAsync. It is safe to call its methods and read its channels from any
+// number of goroutines.
+type Playback struct {
+	handle ari.PlaybackHandle
+
+	startCh chan struct{}
+	stopCh  chan struct{}
+	quitCh  chan struct{}
+
+	mu           sync.Mutex
+	state        PlaybackState
+	stateCh      chan PlaybackState
+	pending      pendingOp
+	jumpDuration time.Duration
+
+	err error
+}
+
+// StartCh returns a channel which is closed once the playback has started
+// (or has failed to start).
+func (pb *Playback) StartCh() <-chan struct{} {
+	return pb.startCh
+}
+
+// StopCh returns a channel which is closed once the playback has stopped,
+// whether normally, due to an error, or because it was cancelled.
+func (pb *Playback) StopCh() <-chan struct{} {
+	return pb.stopCh
+}
+
+// Err returns any error encountered during the playback.
+func (pb *Playback) Err() error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.err
+}
+
+// Cancel stops tracking of the playback and releases its resources. It does
+// not stop the playback itself; for that, use Stop.
+func (pb *Playback) Cancel() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	select {
+	case <-pb.quitCh:
+	default:
+		close(pb.quitCh)
+	}
+}
+
+// State returns the playback's current state.
+func (pb *Playback) State() PlaybackState {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.state
+}
+
+// StateCh returns a channel on which state transitions are delivered. It is
+// buffered, and a transition is dropped rather than blocking the playback's
+// internal goroutine if the subscriber falls behind; callers that need
+// every transition should poll State() alongside it.
+func (pb *Playback) StateCh() <-chan PlaybackState {
+	return pb.stateCh
+}
+
+// setState updates the playback's state and notifies StateCh, acquiring the
+// lock itself.
+func (pb *Playback) setState(s PlaybackState) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.setStateLocked(s)
+}
+
+// setStateLocked is setState for callers which already hold pb.mu.
+func (pb *Playback) setStateLocked(s PlaybackState) {
+	pb.state = s
+	select {
+	case pb.stateCh <- s:
+	default:
+	}
+}
+
+// JumpDuration returns the duration associated with Forward/Reverse.
+func (pb *Playback) JumpDuration() time.Duration {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.jumpDuration
+}
+
+// SetJumpDuration overrides the duration associated with Forward/Reverse.
+func (pb *Playback) SetJumpDuration(d time.Duration) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.jumpDuration = d
+}
+
+// Pause pauses the playback. If called before the playback has started, the
+// pause is queued and applied as soon as it does.
+func (pb *Playback) Pause() error {
+	pb.mu.Lock()
+	state := pb.state
+	pb.mu.Unlock()
+
+	switch state {
+	case Idle, Starting:
+		pb.mu.Lock()
+		pb.pending = opPause
+		pb.mu.Unlock()
+		return nil
+	case Paused:
+		return nil
+	case Playing:
+	default:
+		return fmt.Errorf("audio: cannot pause from state %s", state)
+	}
+
+	if err := pb.handle.Control("pause"); err != nil {
+		return err
+	}
+	pb.setState(Paused)
+	return nil
+}
+
+// Resume resumes a paused playback. If called before the playback has
+// started, it cancels a previously queued Pause.
+func (pb *Playback) Resume() error {
+	pb.mu.Lock()
+	state := pb.state
+	pb.mu.Unlock()
+
+	switch state {
+	case Idle, Starting:
+		pb.mu.Lock()
+		pb.pending = opNone
+		pb.mu.Unlock()
+		return nil
+	case Playing:
+		return nil
+	case Paused:
+	default:
+		return fmt.Errorf("audio: cannot resume from state %s", state)
+	}
+
+	if err := pb.handle.Control("unpause"); err != nil {
+		return err
+	}
+	pb.setState(Playing)
+	return nil
+}
+
+// Restart restarts the playback from the beginning.
+func (pb *Playback) Restart() error {
+	pb.mu.Lock()
+	state := pb.state
+	pb.mu.Unlock()
+
+	if state != Playing && state != Paused {
+		return fmt.Errorf("audio: cannot restart from state %s", state)
+	}
+
+	if err := pb.handle.Control("restart"); err != nil {
+		return err
+	}
+	pb.setState(Playing)
+	return nil
+}
+
+// Forward skips the playback ahead by Asterisk's skip amount for this
+// playback. JumpDuration is not sent to Asterisk and does not change how far
+// it skips.
+func (pb *Playback) Forward() error {
+	return pb.jump("forward")
+}
+
+// Reverse rewinds the playback by Asterisk's skip amount for this playback.
+// JumpDuration is not sent to Asterisk and does not change how far it
+// rewinds.
+func (pb *Playback) Reverse() error {
+	return pb.jump("reverse")
+}
+
+func (pb *Playback) jump(operation string) error {
+	pb.mu.Lock()
+	state := pb.state
+	pb.mu.Unlock()
+
+	if state != Playing && state != Paused {
+		return fmt.Errorf("audio: cannot %s from state %s", operation, state)
+	}
+	return pb.handle.Control(operation)
+}
+
+// Stop halts the playback outright and releases its resources.
+func (pb *Playback) Stop() error {
+	pb.mu.Lock()
+	state := pb.state
+	pb.mu.Unlock()
+
+	if state == Stopped || state == Failed {
+		return nil
+	}
+
+	err := pb.handle.Stop()
+	pb.setState(Stopped)
+	pb.Cancel()
+	return err
+}
+
+// watch drives the playback's state machine off of tracker, in place of the
+// hand-rolled, per-call subscription loop PlayAsync originally used. It is
+// launched as a goroutine by playAsync.
+func (pb *Playback) watch(tracker PlaybackTracker, playbackID string, startTimeout, maxPlayTime time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-pb.quitCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	started, continuing, finished, err := tracker.Track(ctx, playbackID)
+	if err != nil {
+		pb.mu.Lock()
+		pb.err = err
+		pb.mu.Unlock()
+		pb.setState(Failed)
+		close(pb.startCh)
+		close(pb.stopCh)
+		return
+	}
+
+	select {
+	case <-started:
+	case <-time.After(startTimeout):
+		Logger.Error("Playback timed out", "id", playbackID)
+		pb.mu.Lock()
+		pb.err = timeoutErr{"Timeout waiting for start of playback"}
+		pb.mu.Unlock()
+		pb.setState(Failed)
+		close(pb.startCh)
+		close(pb.stopCh)
+		return
+	case <-pb.quitCh:
+		close(pb.startCh)
+		close(pb.stopCh)
+		return
+	}
+
+	Logger.Debug("Playback started", "id", playbackID)
+
+	// Apply any Pause requested before the playback actually started, and
+	// set the post-start state under the same lock acquisition used to read
+	// it, so a concurrent Stop() (which holds pb.mu while it transitions to
+	// Stopped) can't have its terminal state clobbered back to
+	// Playing/Paused by this goroutine.
+	pb.mu.Lock()
+	pending := pb.pending
+	pb.pending = opNone
+
+	if pb.state == Stopped || pb.state == Failed {
+		pb.mu.Unlock()
+		close(pb.startCh)
+		close(pb.stopCh)
+		return
+	}
+
+	if pending == opPause {
+		if cerr := pb.handle.Control("pause"); cerr != nil {
+			Logger.Error("failed to apply queued pause", "err", cerr)
+			pb.setStateLocked(Playing)
+		} else {
+			pb.setStateLocked(Paused)
+		}
+	} else {
+		pb.setStateLocked(Playing)
+	}
+	pb.mu.Unlock()
+
+	close(pb.startCh)
+	defer close(pb.stopCh)
+
+	stopTimer := time.After(maxPlayTime)
+	for {
+		select {
+		case <-finished:
+			Logger.Debug("Playback stopped", "id", playbackID)
+			pb.setState(Stopped)
+			return
+		case <-continuing:
+			// Asterisk has moved a multi-file playback on to its next file;
+			// it is once again actively playing.
+			Logger.Debug("Playback continuing", "id", playbackID)
+			pb.setState(Playing)
+		case <-stopTimer:
+			Logger.Error("Playback timed out", "id", playbackID)
+			pb.mu.Lock()
+			pb.err = timeoutErr{"Timeout waiting for stop of playback"}
+			pb.mu.Unlock()
+			pb.setState(Failed)
+			return
+		case <-pb.quitCh:
+			return
+		}
+	}
+}
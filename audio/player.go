@@ -0,0 +1,9 @@
+package audio
+
+import "github.com/CyCoreSystems/ari"
+
+// Player is anything capable of starting an audio playback, such as an ARI
+// channel or bridge handle.
+type Player interface {
+	Play(mediaURI string) (ari.PlaybackHandle, error)
+}
@@ -0,0 +1,289 @@
+package audio
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/CyCoreSystems/ari"
+	v2 "github.com/CyCoreSystems/ari/v2"
+
+	"golang.org/x/net/context"
+)
+
+// ErrEmptyPlaylist is returned by PlayList when given no media URIs to play.
+var ErrEmptyPlaylist = errors.New("audio: PlayList requires at least one media URI")
+
+// ListItem describes a single media URI as it starts or finishes playing
+// within a PlaybackList.
+type ListItem struct {
+	URI string
+	ID  string
+}
+
+// ListOptions configures the behavior of a PlaybackList.
+type ListOptions struct {
+	// InterruptDTMF is the set of digits which interrupt the currently
+	// playing item when received on ChannelDtmfReceived. It defaults to ""
+	// (no interrupt); set it to audio.AllDTMF to interrupt on any digit.
+	InterruptDTMF string
+
+	// OnDTMF is called for each interrupting digit received. Returning true
+	// advances the list to the next item; returning false stops the list
+	// outright. If nil, any interrupting digit advances the list.
+	OnDTMF func(digit rune, list *PlaybackList) (advance bool)
+}
+
+// PlaybackList plays a sequence of media URIs back-to-back on a single
+// Player, advancing automatically as each item finishes, analogous to a
+// playlist/queue in a traditional media player.
+type PlaybackList struct {
+	mu sync.Mutex
+
+	bus  ari.Subscriber
+	p    Player
+	uris []string
+	opts ListOptions
+
+	index   int
+	digits  []rune
+	current *Playback
+
+	itemStartedCh  chan ListItem
+	itemFinishedCh chan ListItem
+
+	skipCh chan int
+	quitCh chan struct{}
+	doneCh chan struct{}
+
+	err error
+}
+
+// PlayList plays mediaURIs back-to-back on p, advancing to the next URI as
+// each playback finishes.
+func PlayList(ctx context.Context, bus ari.Subscriber, p Player, mediaURIs []string, opts ListOptions) (*PlaybackList, error) {
+	if len(mediaURIs) == 0 {
+		return nil, ErrEmptyPlaylist
+	}
+
+	pl := &PlaybackList{
+		bus:            bus,
+		p:              p,
+		uris:           mediaURIs,
+		opts:           opts,
+		index:          -1,
+		itemStartedCh:  make(chan ListItem, 1),
+		itemFinishedCh: make(chan ListItem, 1),
+		skipCh:         make(chan int, 1),
+		quitCh:         make(chan struct{}),
+		doneCh:         make(chan struct{}),
+	}
+
+	hasDTMF := opts.InterruptDTMF != ""
+
+	var dtmf ari.Subscription
+	if hasDTMF {
+		dtmf = bus.Subscribe("ChannelDtmfReceived")
+	}
+
+	go pl.run(ctx, dtmf, hasDTMF)
+
+	return pl, nil
+}
+
+func (pl *PlaybackList) run(ctx context.Context, dtmf ari.Subscription, hasDTMF bool) {
+	defer close(pl.doneCh)
+	if hasDTMF {
+		defer dtmf.Cancel()
+	}
+
+	var dtmfCh <-chan v2.Event
+	if hasDTMF {
+		dtmfCh = dtmf.C
+	}
+
+	delta := 1 // the first advance moves onto item 0
+	for {
+		pl.mu.Lock()
+		next := pl.index + delta
+		if next < 0 {
+			next = 0
+		}
+		if next >= len(pl.uris) {
+			pl.mu.Unlock()
+			return
+		}
+		pl.index = next
+		uri := pl.uris[next]
+		pl.mu.Unlock()
+
+		pb, err := PlayAsync(pl.bus, pl.p, uri)
+		if err != nil {
+			pl.mu.Lock()
+			pl.err = err
+			pl.mu.Unlock()
+			Logger.Error("PlaybackList item failed to start", "uri", uri, "err", err)
+			return
+		}
+
+		pl.mu.Lock()
+		pl.current = pb
+		pl.mu.Unlock()
+
+		select {
+		case <-pb.StartCh():
+			if err := pb.Err(); err != nil {
+				pl.mu.Lock()
+				pl.err = err
+				pl.mu.Unlock()
+				Logger.Error("PlaybackList item failed to start", "uri", uri, "err", err)
+				pb.Cancel()
+				return
+			}
+			pl.sendItem(pl.itemStartedCh, uri, pb)
+		case <-pl.quitCh:
+			pb.Stop()
+			return
+		}
+
+		delta = 1
+
+	waitLoop:
+		for {
+			select {
+			case <-pb.StopCh():
+				pl.sendItem(pl.itemFinishedCh, uri, pb)
+				break waitLoop
+			case d := <-pl.skipCh:
+				delta = d
+				pb.Stop()
+				break waitLoop
+			case v := <-dtmfCh:
+				e, ok := v.(*v2.ChannelDtmfReceived)
+				if !ok || e.Digit == "" || !containsRune(pl.opts.InterruptDTMF, []rune(e.Digit)[0]) {
+					continue waitLoop
+				}
+
+				digit := []rune(e.Digit)[0]
+				pl.mu.Lock()
+				pl.digits = append(pl.digits, digit)
+				pl.mu.Unlock()
+
+				pb.Stop()
+
+				advance := true
+				if pl.opts.OnDTMF != nil {
+					advance = pl.opts.OnDTMF(digit, pl)
+				}
+				if !advance {
+					return
+				}
+				break waitLoop
+			case <-pl.quitCh:
+				pb.Stop()
+				return
+			case <-ctx.Done():
+				pb.Cancel()
+				pl.mu.Lock()
+				pl.err = ctx.Err()
+				pl.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+func (pl *PlaybackList) sendItem(ch chan ListItem, uri string, pb *Playback) {
+	var id string
+	if data, err := pb.handle.Data(); err == nil {
+		id = data.ID
+	}
+
+	item := ListItem{URI: uri, ID: id}
+	select {
+	case ch <- item:
+	default:
+	}
+}
+
+func containsRune(s string, r rune) bool {
+	for _, c := range s {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Next advances to the next item in the list, interrupting the current one.
+func (pl *PlaybackList) Next() {
+	pl.requestSkip(1)
+}
+
+// Previous returns to the previous item in the list, interrupting the
+// current one.
+func (pl *PlaybackList) Previous() {
+	pl.requestSkip(-1)
+}
+
+// Skip jumps n items forward (or, if negative, backward) relative to the
+// current item, interrupting the current one.
+func (pl *PlaybackList) Skip(n int) {
+	pl.requestSkip(n)
+}
+
+func (pl *PlaybackList) requestSkip(n int) {
+	select {
+	case pl.skipCh <- n:
+	default:
+	}
+}
+
+// Index returns the index of the currently (or most recently) playing item.
+func (pl *PlaybackList) Index() int {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return pl.index
+}
+
+// ReceivedDigits returns the DTMF digits collected so far via InterruptDTMF.
+func (pl *PlaybackList) ReceivedDigits() string {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return string(pl.digits)
+}
+
+// ItemStartedCh returns a channel delivering each item's URI and playback ID
+// as it starts playing. It buffers one item; an item is dropped if the
+// previous one has not yet been received.
+func (pl *PlaybackList) ItemStartedCh() <-chan ListItem {
+	return pl.itemStartedCh
+}
+
+// ItemFinishedCh returns a channel delivering each item's URI and playback
+// ID as it finishes playing. It buffers one item; an item is dropped if the
+// previous one has not yet been received.
+func (pl *PlaybackList) ItemFinishedCh() <-chan ListItem {
+	return pl.itemFinishedCh
+}
+
+// DoneCh returns a channel which is closed once the list has finished
+// playing all of its items, was stopped, or errored out.
+func (pl *PlaybackList) DoneCh() <-chan struct{} {
+	return pl.doneCh
+}
+
+// Err returns any error encountered while playing the list.
+func (pl *PlaybackList) Err() error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return pl.err
+}
+
+// Stop halts playback of the list and releases its resources.
+func (pl *PlaybackList) Stop() {
+	select {
+	case <-pl.quitCh:
+	default:
+		close(pl.quitCh)
+	}
+}
@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/CyCoreSystems/ari"
+
+	"golang.org/x/net/context"
+)
+
+// RetryPolicy controls how PlayWithRetry retries a failed playback attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times to attempt the playback,
+	// including the first. A value <= 0 is treated as 1.
+	MaxAttempts int
+
+	// PerAttemptTimeout bounds how long a single attempt may take before it
+	// is cancelled and retried. Zero means no per-attempt bound beyond the
+	// playback's own start/stop timeouts.
+	PerAttemptTimeout time.Duration
+
+	// Backoff returns how long to wait before the given attempt number
+	// (1-indexed) is retried. If nil, a failed attempt is retried
+	// immediately.
+	Backoff func(attempt int) time.Duration
+
+	// RetryOn decides whether a given attempt's error should be retried. If
+	// nil, timeouts and transport (net.Error) errors are retried and any
+	// other error is not.
+	RetryOn func(err error) bool
+
+	// PlayOptions overrides PlaybackStartTimeout and MaxPlaybackTime for
+	// every attempt, since the package-level variables are not safe to tune
+	// concurrently.
+	PlayOptions PlayOptions
+}
+
+// defaultRetryOn retries the existing timeoutErr (Asterisk dropping or
+// re-ordering PlaybackStarted) and transport errors from p.Play (anything
+// satisfying net.Error), but not permanent failures such as a bad media URI
+// or an ARI-level rejection, which should fail fast instead of burning the
+// whole retry budget.
+func defaultRetryOn(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryErr collects the errors of every failed attempt made by
+// PlayWithRetry.
+type retryErr struct {
+	attempts []error
+}
+
+func (e *retryErr) Error() string {
+	msg := fmt.Sprintf("audio: playback failed after %d attempt(s)", len(e.attempts))
+	for i, err := range e.attempts {
+		msg += fmt.Sprintf("; attempt %d: %v", i+1, err)
+	}
+	return msg
+}
+
+// PlayWithRetry plays mediaURI, retrying according to policy when an
+// attempt fails. It targets the failure mode the current code silently
+// converts into a bare timeoutErr -- in production this happens when
+// Asterisk drops or re-orders PlaybackStarted, and callers otherwise have
+// to reimplement this retry loop themselves.
+func PlayWithRetry(ctx context.Context, bus ari.Subscriber, p Player, mediaURI string, policy RetryPolicy) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	retryOn := policy.RetryOn
+	if retryOn == nil {
+		retryOn = defaultRetryOn
+	}
+
+	var errs []error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attemptCtx := ctx
+		var cancel context.CancelFunc
+		if policy.PerAttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.PerAttemptTimeout)
+		}
+
+		err := playOnce(attemptCtx, bus, p, mediaURI, policy.PlayOptions)
+
+		if cancel != nil {
+			cancel()
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		Logger.Error("PlayWithRetry attempt failed", "attempt", attempt, "err", err)
+		errs = append(errs, err)
+
+		if attempt == maxAttempts || !retryOn(err) {
+			break
+		}
+
+		if policy.Backoff != nil {
+			select {
+			case <-time.After(policy.Backoff(attempt)):
+			case <-ctx.Done():
+				return &retryErr{attempts: errs}
+			}
+		}
+	}
+
+	return &retryErr{attempts: errs}
+}
+
+// playOnce runs a single PlayWithRetry attempt, waiting for it to finish (or
+// the attempt's context to expire) and cancelling the playback either way.
+func playOnce(ctx context.Context, bus ari.Subscriber, p Player, mediaURI string, opts PlayOptions) error {
+	pb, err := PlayAsyncWithOptions(bus, p, mediaURI, opts)
+	if err != nil {
+		return err
+	}
+	defer pb.Cancel()
+
+	select {
+	case <-pb.StopCh():
+		return pb.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,249 @@
+package audio
+
+import (
+	"sync"
+
+	"github.com/CyCoreSystems/ari"
+	v2 "github.com/CyCoreSystems/ari/v2"
+
+	"golang.org/x/net/context"
+)
+
+// EventSource abstracts over the event stream a PlaybackTracker watches. An
+// ari.Subscriber (subscribing once per call) satisfies it directly, but it
+// also allows a recorded/replay source for tests, or a MultiplexedTracker's
+// own single subscription shared across many concurrent PlayAsync calls.
+type EventSource interface {
+	Subscribe(n ...string) ari.Subscription
+}
+
+// PlaybackTracker watches for the start, continuation, and finish of a
+// single playback ID, independently of how the underlying ARI events are
+// delivered.
+type PlaybackTracker interface {
+	// Track watches playbackID's events until it starts and finishes, or
+	// ctx is done. started is closed once the playback starts (or, if it
+	// finishes before starting, is closed at the same time as finished).
+	// continuing receives a value each time Asterisk reports the playback
+	// continuing on to its next file (PlaybackContinuing), which callers use
+	// to recognize that a multi-file playback has resumed playing. finished
+	// is closed once the playback finishes or ctx is done. Track only
+	// returns a non-nil error if it is unable to begin watching.
+	Track(ctx context.Context, playbackID string) (started <-chan struct{}, continuing <-chan struct{}, finished <-chan struct{}, err error)
+}
+
+// defaultTracker is the default PlaybackTracker, preserving the behavior
+// PlayAsync always had: one subscription per tracked playback, matched by
+// ID via a linear scan of the event stream.
+type defaultTracker struct {
+	source EventSource
+}
+
+// NewPlaybackTracker constructs the default PlaybackTracker, backed by
+// source. Pass the bus (ari.Subscriber) for normal operation, or a recorded
+// EventSource to replay captured events in tests. For high-concurrency
+// dialplans, prefer a MultiplexedTracker instead, which shares a single
+// subscription across many tracked playbacks.
+func NewPlaybackTracker(source EventSource) PlaybackTracker {
+	return &defaultTracker{source: source}
+}
+
+func (t *defaultTracker) Track(ctx context.Context, playbackID string) (<-chan struct{}, <-chan struct{}, <-chan struct{}, error) {
+	s := t.source.Subscribe("PlaybackStarted", "PlaybackFinished", "PlaybackContinuing")
+
+	started := make(chan struct{})
+	continuing := make(chan struct{}, 4)
+	finished := make(chan struct{})
+
+	go func() {
+		defer s.Cancel()
+		defer close(finished)
+
+		startedOnce := false
+		for {
+			select {
+			case <-ctx.Done():
+				if !startedOnce {
+					close(started)
+				}
+				return
+			case v := <-s.C:
+				if v == nil {
+					continue
+				}
+				switch e := v.(type) {
+				case *v2.PlaybackStarted:
+					if e.Playback.ID != playbackID {
+						continue
+					}
+					if !startedOnce {
+						startedOnce = true
+						close(started)
+					}
+				case *v2.PlaybackContinuing:
+					if e.Playback.ID != playbackID {
+						continue
+					}
+					select {
+					case continuing <- struct{}{}:
+					default:
+					}
+				case *v2.PlaybackFinished:
+					if e.Playback.ID != playbackID {
+						continue
+					}
+					if !startedOnce {
+						startedOnce = true
+						close(started)
+					}
+					return
+				}
+			}
+		}
+	}()
+
+	return started, continuing, finished, nil
+}
+
+// MultiplexedTracker is a PlaybackTracker that maintains a single
+// subscription regardless of how many playbacks are being tracked
+// concurrently, dispatching events to each caller by playback ID via a
+// map[string]chan v2.Event guarded by a mutex. Use it in place of the
+// default tracker in high-concurrency dialplans, where one subscription
+// (and matching goroutine) per PlayAsync call does not scale.
+type MultiplexedTracker struct {
+	s ari.Subscription
+
+	mu   sync.Mutex
+	subs map[string]chan v2.Event
+
+	closeOnce sync.Once
+}
+
+// NewMultiplexedTracker constructs a MultiplexedTracker backed by a single
+// subscription against source.
+func NewMultiplexedTracker(source EventSource) *MultiplexedTracker {
+	t := &MultiplexedTracker{
+		s:    source.Subscribe("PlaybackStarted", "PlaybackFinished", "PlaybackContinuing"),
+		subs: make(map[string]chan v2.Event),
+	}
+
+	go t.dispatch()
+
+	return t
+}
+
+func (t *MultiplexedTracker) dispatch() {
+	for v := range t.s.C {
+		if v == nil {
+			continue
+		}
+
+		var id string
+		switch e := v.(type) {
+		case *v2.PlaybackStarted:
+			id = e.Playback.ID
+		case *v2.PlaybackContinuing:
+			id = e.Playback.ID
+		case *v2.PlaybackFinished:
+			id = e.Playback.ID
+		default:
+			continue
+		}
+
+		t.mu.Lock()
+		ch, ok := t.subs[id]
+		if ok && v.GetType() == "PlaybackFinished" {
+			delete(t.subs, id)
+		}
+		t.mu.Unlock()
+
+		if !ok {
+			continue
+		}
+
+		select {
+		case ch <- v:
+		default:
+			Logger.Debug("Dropping playback event for slow tracker subscriber", "id", id)
+		}
+
+		if v.GetType() == "PlaybackFinished" {
+			close(ch)
+		}
+	}
+
+	// The subscription is gone; release every playback still being tracked.
+	t.mu.Lock()
+	for id, ch := range t.subs {
+		delete(t.subs, id)
+		close(ch)
+	}
+	t.mu.Unlock()
+}
+
+// Track implements PlaybackTracker.
+func (t *MultiplexedTracker) Track(ctx context.Context, playbackID string) (<-chan struct{}, <-chan struct{}, <-chan struct{}, error) {
+	ch := make(chan v2.Event, 4)
+
+	t.mu.Lock()
+	t.subs[playbackID] = ch
+	t.mu.Unlock()
+
+	started := make(chan struct{})
+	continuing := make(chan struct{}, 4)
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		startedOnce := false
+		for {
+			select {
+			case <-ctx.Done():
+				t.mu.Lock()
+				delete(t.subs, playbackID)
+				t.mu.Unlock()
+				if !startedOnce {
+					close(started)
+				}
+				return
+			case v, ok := <-ch:
+				if !ok {
+					if !startedOnce {
+						close(started)
+					}
+					return
+				}
+				switch v.(type) {
+				case *v2.PlaybackStarted:
+					if !startedOnce {
+						startedOnce = true
+						close(started)
+					}
+				case *v2.PlaybackContinuing:
+					select {
+					case continuing <- struct{}{}:
+					default:
+					}
+				case *v2.PlaybackFinished:
+					if !startedOnce {
+						startedOnce = true
+						close(started)
+					}
+				}
+			}
+		}
+	}()
+
+	return started, continuing, finished, nil
+}
+
+// Close cancels the MultiplexedTracker's underlying subscription. Any
+// in-flight Track calls will see their finished channel close without
+// having seen a terminal event.
+func (t *MultiplexedTracker) Close() {
+	t.closeOnce.Do(func() {
+		t.s.Cancel()
+	})
+}
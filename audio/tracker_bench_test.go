@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/CyCoreSystems/ari"
+	v2 "github.com/CyCoreSystems/ari/v2"
+
+	"golang.org/x/net/context"
+)
+
+// countingSource is an EventSource whose only job is to count how many
+// subscriptions get opened against it, so the benchmarks below can report
+// how many underlying subscriptions each tracker needs to watch N concurrent
+// playbacks.
+type countingSource struct {
+	subscribes int
+}
+
+func (s *countingSource) Subscribe(n ...string) ari.Subscription {
+	s.subscribes++
+	return ari.Subscription{C: make(chan v2.Event, 1)}
+}
+
+func benchmarkTracker(b *testing.B, newTracker func(EventSource) PlaybackTracker, n int) {
+	for i := 0; i < b.N; i++ {
+		source := &countingSource{}
+		tracker := newTracker(source)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		for j := 0; j < n; j++ {
+			if _, _, _, err := tracker.Track(ctx, strconv.Itoa(j)); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.ReportMetric(float64(source.subscribes), "subscriptions")
+
+		cancel()
+		if closer, ok := tracker.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+}
+
+// BenchmarkDefaultTracker_100Concurrent demonstrates that the default,
+// per-call tracker opens one subscription (and matching goroutine) per
+// tracked playback: subscriptions == N regardless of b.N.
+func BenchmarkDefaultTracker_100Concurrent(b *testing.B) {
+	benchmarkTracker(b, NewPlaybackTracker, 100)
+}
+
+// BenchmarkMultiplexedTracker_100Concurrent demonstrates that
+// MultiplexedTracker shares a single subscription across all of its tracked
+// playbacks: subscriptions == 1 regardless of N.
+func BenchmarkMultiplexedTracker_100Concurrent(b *testing.B) {
+	benchmarkTracker(b, func(source EventSource) PlaybackTracker {
+		return NewMultiplexedTracker(source)
+	}, 100)
+}